@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ImTheHope/2048-royale/server/storage"
+)
+
+// ═══════════════════════════════════════
+//  ACCOUNTS & AUTH
+// ═══════════════════════════════════════
+
+// store is the SQLite-backed accounts/match-history database. It's set
+// once in main; until it's non-nil no bearer token resolves (see
+// authenticate).
+var store *storage.Store
+
+// authTokens maps a bearer token to the account ID it was issued for.
+// Tokens are purely in-memory: restarting the server logs everyone out.
+var (
+	authTokens   = make(map[string]string)
+	authTokensMu sync.Mutex
+)
+
+func issueAuthToken(playerID string) string {
+	raw := make([]byte, 32)
+	rand.Read(raw)
+	token := hex.EncodeToString(raw)
+
+	authTokensMu.Lock()
+	authTokens[token] = playerID
+	authTokensMu.Unlock()
+	return token
+}
+
+func resolveAuthToken(token string) (string, bool) {
+	authTokensMu.Lock()
+	defer authTokensMu.Unlock()
+	playerID, ok := authTokens[token]
+	return playerID, ok
+}
+
+// authenticate resolves a bearer token to an account ID. It always fails
+// (ok == false) while no store is configured, so players keep their
+// per-connection ID then, and with -require-accounts every
+// create/join/find_match is refused.
+func authenticate(token string) (string, bool) {
+	if store == nil || token == "" {
+		return "", false
+	}
+	return resolveAuthToken(token)
+}
+
+type credentials struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// handleRegister creates a new account. Callers still need to POST
+// /login afterwards to get a bearer token.
+func handleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var creds credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil || creds.Name == "" || creds.Password == "" {
+		http.Error(w, "name and password are required", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(creds.Password), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, "could not hash password", http.StatusInternalServerError)
+		return
+	}
+
+	account := storage.Player{
+		ID:           generatePlayerID(),
+		Name:         creds.Name,
+		PasswordHash: string(hash),
+		Rating:       1000,
+		CreatedAt:    time.Now(),
+	}
+	if err := store.CreatePlayer(account); err != nil {
+		http.Error(w, "name already taken", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		ID string `json:"id"`
+	}{ID: account.ID})
+}
+
+// handleLogin checks a name/password pair and, on success, issues a
+// bearer token for the auth field of create/join/find_match. The token is
+// required there only when -require-accounts is set; otherwise it is
+// optional and only replaces the random per-connection player ID.
+func handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var creds credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	account, err := store.PlayerByName(creds.Name)
+	if err != nil {
+		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		return
+	}
+	if bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(creds.Password)) != nil {
+		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Token string `json:"token"`
+	}{Token: issueAuthToken(account.ID)})
+}
+
+// handlePlayerProfile serves GET /players/{id} and GET /players/{id}/matches.
+func handlePlayerProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/players/")
+	id, sub, hasSub := strings.Cut(path, "/")
+
+	if hasSub && sub == "matches" {
+		matches, err := store.MatchesForPlayer(id)
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(matches)
+		return
+	}
+
+	account, err := store.PlayerByID(id)
+	if err != nil {
+		http.Error(w, "player not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		ID        string    `json:"id"`
+		Name      string    `json:"name"`
+		Rating    int       `json:"rating"`
+		CreatedAt time.Time `json:"created_at"`
+	}{ID: account.ID, Name: account.Name, Rating: account.Rating, CreatedAt: account.CreatedAt})
+}
+
+// ═══════════════════════════════════════
+//  RATINGS
+// ═══════════════════════════════════════
+
+const eloK = 32
+
+// eloDelta is the rating change for a player rated `rating` who played an
+// opponent rated `opponent`, given the actual outcome.
+func eloDelta(rating, opponent int, won bool) int {
+	expected := 1 / (1 + math.Pow(10, float64(opponent-rating)/400))
+	actual := 0.0
+	if won {
+		actual = 1.0
+	}
+	return int(math.Round(eloK * (actual - expected)))
+}
+
+func generateMatchID() string {
+	const chars = "abcdefghijklmnopqrstuvwxyz0123456789"
+	id := make([]byte, 12)
+	for i := range id {
+		id[i] = chars[rand.Intn(len(chars))]
+	}
+	return string(id)
+}
+
+// settleMatch updates both players' Elo ratings, persists the match row,
+// and pushes a rating_update to each. It's a no-op without a 2-player
+// room or a configured store (battle-royale rooms settle differently,
+// once that mode exists). Callers must hold room.mu.
+func settleMatch(room *Room, winnerID string) {
+	if store == nil || len(room.Players) != 2 {
+		return
+	}
+	a, b := room.Players[0], room.Players[1]
+
+	a.mu.Lock()
+	scoreA := a.Score
+	a.mu.Unlock()
+	b.mu.Lock()
+	scoreB := b.Score
+	b.mu.Unlock()
+
+	accountA, err := store.PlayerByID(a.ID)
+	if err != nil {
+		log.Printf("settleMatch: lookup %s: %v", a.ID, err)
+		return
+	}
+	accountB, err := store.PlayerByID(b.ID)
+	if err != nil {
+		log.Printf("settleMatch: lookup %s: %v", b.ID, err)
+		return
+	}
+
+	deltaA := eloDelta(accountA.Rating, accountB.Rating, winnerID == a.ID)
+	deltaB := eloDelta(accountB.Rating, accountA.Rating, winnerID == b.ID)
+	newRatingA := accountA.Rating + deltaA
+	newRatingB := accountB.Rating + deltaB
+
+	if err := store.UpdateRating(a.ID, newRatingA); err != nil {
+		log.Printf("settleMatch: update rating %s: %v", a.ID, err)
+	}
+	if err := store.UpdateRating(b.ID, newRatingB); err != nil {
+		log.Printf("settleMatch: update rating %s: %v", b.ID, err)
+	}
+
+	moves := 0
+	for _, ev := range room.Events {
+		if ev.Type == "move" {
+			moves++
+		}
+	}
+
+	err = store.RecordMatch(storage.Match{
+		ID:       generateMatchID(),
+		Room:     room.Code,
+		PlayerA:  a.ID,
+		PlayerB:  b.ID,
+		Winner:   winnerID,
+		ScoreA:   scoreA,
+		ScoreB:   scoreB,
+		Moves:    moves,
+		Duration: time.Since(room.StartedAt),
+		EndedAt:  time.Now(),
+	})
+	if err != nil {
+		log.Printf("settleMatch: record match in room %s: %v", room.Code, err)
+	}
+
+	sendJSON(a.Conn, Message{Type: "rating_update", Delta: deltaA, NewRating: newRatingA})
+	sendJSON(b.Conn, Message{Type: "rating_update", Delta: deltaB, NewRating: newRatingB})
+}
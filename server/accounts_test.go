@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestEloDeltaEqualRatingsWinLoss(t *testing.T) {
+	if got := eloDelta(1000, 1000, true); got != 16 {
+		t.Errorf("eloDelta(1000, 1000, true) = %d, want 16", got)
+	}
+	if got := eloDelta(1000, 1000, false); got != -16 {
+		t.Errorf("eloDelta(1000, 1000, false) = %d, want -16", got)
+	}
+}
+
+func TestEloDeltaUnderdogWinGainsMore(t *testing.T) {
+	underdogWin := eloDelta(900, 1100, true)
+	favoriteWin := eloDelta(1100, 900, true)
+	if underdogWin <= favoriteWin {
+		t.Errorf("underdog win delta %d should exceed favorite win delta %d", underdogWin, favoriteWin)
+	}
+}
+
+func TestEloDeltaZeroSum(t *testing.T) {
+	a := eloDelta(1200, 1400, true)
+	b := eloDelta(1400, 1200, false)
+	if a != -b {
+		t.Errorf("eloDelta(1200,1400,true)=%d and eloDelta(1400,1200,false)=%d should be equal and opposite", a, b)
+	}
+}
@@ -0,0 +1,165 @@
+// Package game implements server-authoritative 2048 rules: sliding/merging
+// a board and spawning new tiles. Keeping this off the client means a
+// player can only ever reach the scores and wins their real moves produce.
+package game
+
+import "math/rand"
+
+// Direction is one of the four legal moves.
+type Direction int
+
+const (
+	Up Direction = iota
+	Down
+	Left
+	Right
+)
+
+// ParseDirection maps the wire string from a "move" message to a Direction.
+func ParseDirection(s string) (Direction, bool) {
+	switch s {
+	case "up":
+		return Up, true
+	case "down":
+		return Down, true
+	case "left":
+		return Left, true
+	case "right":
+		return Right, true
+	default:
+		return 0, false
+	}
+}
+
+// Board is a 4x4 grid of tile values; 0 means empty.
+type Board [4][4]int
+
+// Move slides and merges every row/column toward dir. It reports whether
+// anything on the board actually changed (a no-op move must not spawn a
+// tile or cost a turn) and how many points the merges were worth.
+func (b *Board) Move(dir Direction) (moved bool, gained int) {
+	before := *b
+
+	switch dir {
+	case Left:
+		for r := 0; r < 4; r++ {
+			b[r], gained = slideRow(b[r], gained)
+		}
+	case Right:
+		for r := 0; r < 4; r++ {
+			reversed := reverse(b[r])
+			reversed, gained = slideRow(reversed, gained)
+			b[r] = reverse(reversed)
+		}
+	case Up:
+		for c := 0; c < 4; c++ {
+			col := b.column(c)
+			col, gained = slideRow(col, gained)
+			b.setColumn(c, col)
+		}
+	case Down:
+		for c := 0; c < 4; c++ {
+			col := reverse(b.column(c))
+			col, gained = slideRow(col, gained)
+			b.setColumn(c, reverse(col))
+		}
+	}
+
+	return *b != before, gained
+}
+
+// slideRow compacts a row left, merging equal adjacent tiles once each,
+// and returns the running score total with any merge gains added.
+func slideRow(row [4]int, gained int) ([4]int, int) {
+	vals := make([]int, 0, 4)
+	for _, v := range row {
+		if v != 0 {
+			vals = append(vals, v)
+		}
+	}
+
+	merged := make([]int, 0, 4)
+	for i := 0; i < len(vals); i++ {
+		if i+1 < len(vals) && vals[i] == vals[i+1] {
+			merged = append(merged, vals[i]*2)
+			gained += vals[i] * 2
+			i++
+		} else {
+			merged = append(merged, vals[i])
+		}
+	}
+
+	var out [4]int
+	copy(out[:], merged)
+	return out, gained
+}
+
+func reverse(row [4]int) [4]int {
+	return [4]int{row[3], row[2], row[1], row[0]}
+}
+
+func (b *Board) column(c int) [4]int {
+	return [4]int{b[0][c], b[1][c], b[2][c], b[3][c]}
+}
+
+func (b *Board) setColumn(c int, col [4]int) {
+	for r := 0; r < 4; r++ {
+		b[r][c] = col[r]
+	}
+}
+
+// SpawnTile places a 2 (90% of the time) or a 4 (10%) on a random empty
+// cell and reports which cell/value it picked, so callers can log the
+// draw for a replay. It's a no-op (spawned == false) if the board is full.
+func (b *Board) SpawnTile(rng *rand.Rand) (cell [2]int, value int, spawned bool) {
+	empty := make([][2]int, 0, 16)
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			if b[r][c] == 0 {
+				empty = append(empty, [2]int{r, c})
+			}
+		}
+	}
+	if len(empty) == 0 {
+		return cell, 0, false
+	}
+
+	cell = empty[rng.Intn(len(empty))]
+	value = 2
+	if rng.Float64() < 0.1 {
+		value = 4
+	}
+	b[cell[0]][cell[1]] = value
+	return cell, value, true
+}
+
+// Has2048 reports whether any tile has reached the 2048 win condition.
+func (b *Board) Has2048() bool {
+	for _, row := range b {
+		for _, v := range row {
+			if v >= 2048 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasMoves reports whether any legal move would change the board, i.e.
+// there's an empty cell or two adjacent equal tiles.
+func (b *Board) HasMoves() bool {
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			if b[r][c] == 0 {
+				return true
+			}
+			if c+1 < 4 && b[r][c] == b[r][c+1] {
+				return true
+			}
+			if r+1 < 4 && b[r][c] == b[r+1][c] {
+				return true
+			}
+		}
+	}
+	return false
+}
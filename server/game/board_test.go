@@ -0,0 +1,140 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBoardMoveSlideAndMerge(t *testing.T) {
+	tests := []struct {
+		name   string
+		board  Board
+		dir    Direction
+		want   Board
+		moved  bool
+		gained int
+	}{
+		{
+			name:   "left merges equal adjacent tiles once",
+			board:  Board{{2, 2, 2, 2}, {}, {}, {}},
+			dir:    Left,
+			want:   Board{{4, 4, 0, 0}, {}, {}, {}},
+			moved:  true,
+			gained: 8,
+		},
+		{
+			name:   "right compacts without merging distinct tiles",
+			board:  Board{{2, 0, 4, 0}, {}, {}, {}},
+			dir:    Right,
+			want:   Board{{0, 0, 2, 4}, {}, {}, {}},
+			moved:  true,
+			gained: 0,
+		},
+		{
+			name:   "up slides a column toward the top",
+			board:  Board{{0, 0, 0, 0}, {0, 0, 0, 0}, {2, 0, 0, 0}, {2, 0, 0, 0}},
+			dir:    Up,
+			want:   Board{{4, 0, 0, 0}, {}, {}, {}},
+			moved:  true,
+			gained: 4,
+		},
+		{
+			name:   "no-op move reports moved=false",
+			board:  Board{{2, 4, 0, 0}, {}, {}, {}},
+			dir:    Left,
+			want:   Board{{2, 4, 0, 0}, {}, {}, {}},
+			moved:  false,
+			gained: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.board
+			moved, gained := b.Move(tt.dir)
+			if moved != tt.moved {
+				t.Errorf("moved = %v, want %v", moved, tt.moved)
+			}
+			if gained != tt.gained {
+				t.Errorf("gained = %d, want %d", gained, tt.gained)
+			}
+			if b != tt.want {
+				t.Errorf("board = %v, want %v", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoardSpawnTileOnlyUsesEmptyCells(t *testing.T) {
+	var b Board
+	b[0][0] = 2 // fill every cell except (0,1), which is left as the only empty one
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			if r != 0 || c != 1 {
+				b[r][c] = 2
+			}
+		}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	cell, value, spawned := b.SpawnTile(rng)
+	if !spawned {
+		t.Fatal("spawned = false, want true")
+	}
+	if cell != [2]int{0, 1} {
+		t.Errorf("cell = %v, want the only empty cell {0 1}", cell)
+	}
+	if value != 2 && value != 4 {
+		t.Errorf("value = %d, want 2 or 4", value)
+	}
+}
+
+func TestBoardSpawnTileNoopWhenFull(t *testing.T) {
+	var b Board
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			b[r][c] = 2
+		}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	_, _, spawned := b.SpawnTile(rng)
+	if spawned {
+		t.Error("spawned = true on a full board, want false")
+	}
+}
+
+func TestBoardHas2048(t *testing.T) {
+	var b Board
+	if b.Has2048() {
+		t.Error("Has2048() = true on an empty board, want false")
+	}
+	b[2][3] = 2048
+	if !b.Has2048() {
+		t.Error("Has2048() = false with a 2048 tile present, want true")
+	}
+}
+
+func TestBoardHasMoves(t *testing.T) {
+	stuck := Board{
+		{2, 4, 2, 4},
+		{4, 2, 4, 2},
+		{2, 4, 2, 4},
+		{4, 2, 4, 2},
+	}
+	if stuck.HasMoves() {
+		t.Error("HasMoves() = true on a full checkerboard with no equal neighbors, want false")
+	}
+
+	fullWithMerge := stuck
+	fullWithMerge[3][3] = 2 // full board, but now (3,2)=4,(3,3)=2 ... adjust a pair to match
+	fullWithMerge[3][2] = 2
+	if !fullWithMerge.HasMoves() {
+		t.Error("HasMoves() = false on a full board with an adjacent equal pair, want true")
+	}
+
+	var withEmpty Board
+	if !withEmpty.HasMoves() {
+		t.Error("HasMoves() = false on an empty board, want true")
+	}
+}
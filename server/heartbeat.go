@@ -0,0 +1,83 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ═══════════════════════════════════════
+//  HEARTBEAT & IDLE KICK
+// ═══════════════════════════════════════
+
+// idleCheckInterval is how often a room polls its players' LastActive
+// timestamps; it doesn't need to be as tight as idleTimeout itself.
+const idleCheckInterval = 10 * time.Second
+
+// runPinger sends a WebSocket ping every pingInterval until stop is
+// closed or a write fails (the read loop will notice the dead connection
+// via the read deadline and clean up the player).
+func runPinger(conn *websocket.Conn, stop <-chan struct{}) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// monitorIdlePlayers watches a started room for players who stopped
+// sending messages (but never dropped the socket) and kicks them once
+// they've been quiet past idleTimeout. It exits once the room empties out.
+func monitorIdlePlayers(room *Room) {
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		room.mu.Lock()
+		if len(room.Players) == 0 {
+			room.mu.Unlock()
+			return
+		}
+
+		var idle []*Player
+		for _, p := range room.Players {
+			p.mu.Lock()
+			stale := !p.Disconnected && !p.Lost && !p.Won && time.Since(p.LastActive) > idleTimeout
+			p.mu.Unlock()
+			if stale {
+				idle = append(idle, p)
+			}
+		}
+		room.mu.Unlock()
+
+		for _, p := range idle {
+			kickIdlePlayer(room, p)
+		}
+	}
+}
+
+// kickIdlePlayer notifies an idle player, closes their socket, and
+// forfeits the match to the opponent(s) immediately (no reconnect grace
+// period — they were still connected, just not playing).
+func kickIdlePlayer(room *Room, player *Player) {
+	player.mu.Lock()
+	player.Kicked = true
+	player.mu.Unlock()
+
+	sendJSON(player.Conn, Message{Type: "kicked", Message: "idle"})
+	player.Conn.Close()
+
+	room.mu.Lock()
+	forfeit(room, player, "L'adversaire a été exclu pour inactivité")
+	room.mu.Unlock()
+
+	log.Printf("Player %s kicked for idling in room %s", player.ID, room.Code)
+}
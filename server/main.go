@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"math/rand"
@@ -11,6 +12,9 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/ImTheHope/2048-royale/server/game"
+	"github.com/ImTheHope/2048-royale/server/storage"
 )
 
 // ═══════════════════════════════════════
@@ -18,31 +22,58 @@ import (
 // ═══════════════════════════════════════
 
 type Player struct {
-	ID   string
-	Conn *websocket.Conn
-	Grid [4][4]int
-	Score int
-	Lost  bool
-	Won   bool
-	mu    sync.Mutex
+	ID             string
+	Conn           *websocket.Conn
+	Room           *Room // room currently assigned to this player; set by join/create/reconnect and by the matchmaker
+	Board          game.Board
+	Score          int
+	Lost           bool
+	Won            bool
+	Disconnected   bool
+	DisconnectedAt time.Time
+	LastActive     time.Time // bumped on every inbound message; watched by monitorIdlePlayers
+	Kicked         bool      // set right before we close an idle player's socket, so handleDisconnect skips the reconnect grace period
+	reconnectTimer *time.Timer
+	mu             sync.Mutex
 }
 
 type Room struct {
-	Code    string
-	Players []*Player
-	Started bool
-	mu      sync.Mutex
+	Code       string
+	Players    []*Player
+	Started    bool
+	Finished   bool // set once a winner has been declared; blocks a second settle for the same match
+	Public     bool // listed via GET /games and open to spectators joining by code
+	MaxPlayers int  // 2 today; 3-4 reserved for a future battle-royale mode
+	rng        *rand.Rand // shared draw sequence for both players' spawned tiles, seeded at game start
+	Seed       int64
+	StartedAt  time.Time // set by startRoomGame; used to time matches for the match-history record
+	mu         sync.Mutex
+
+	Events  []Event
+	nextSeq int
+
+	Spectators []*websocket.Conn
+	specMu     sync.Mutex
 }
 
 type Message struct {
-	Type      string          `json:"type"`
-	Room      string          `json:"room,omitempty"`
-	Direction string          `json:"direction,omitempty"`
-	PlayerID  string          `json:"player_id,omitempty"`
-	Grid      *[4][4]int      `json:"grid,omitempty"`
-	Score     int             `json:"score,omitempty"`
-	Winner    string          `json:"winner,omitempty"`
-	Message   string          `json:"message,omitempty"`
+	Type          string     `json:"type"`
+	Room          string     `json:"room,omitempty"`
+	Direction     string     `json:"direction,omitempty"`
+	PlayerID      string     `json:"player_id,omitempty"`
+	Token         string     `json:"token,omitempty"`
+	Grid          *[4][4]int `json:"grid,omitempty"`
+	Score         int        `json:"score,omitempty"`
+	OpponentGrid  *[4][4]int `json:"opponent_grid,omitempty"`
+	OpponentScore int        `json:"opponent_score,omitempty"`
+	Lost          bool       `json:"lost,omitempty"`
+	Won           bool       `json:"won,omitempty"`
+	Winner        string     `json:"winner,omitempty"`
+	Message       string     `json:"message,omitempty"`
+	Rating        int        `json:"rating,omitempty"`
+	Auth          string     `json:"auth,omitempty"`       // bearer token from /login; optional unless -require-accounts is set, but resolves Player.ID to a stable account ID so ratings track correctly
+	Delta         int        `json:"delta,omitempty"`      // rating_update: change applied this match
+	NewRating     int        `json:"new_rating,omitempty"` // rating_update: rating after the change
 }
 
 // ═══════════════════════════════════════
@@ -59,6 +90,20 @@ var (
 	}
 )
 
+// pingInterval and idleTimeout are configurable via -ping-interval and
+// -idle-timeout; see flag declarations in main.
+var (
+	pingInterval = 30 * time.Second
+	idleTimeout  = 90 * time.Second
+)
+
+// requireAccounts is configurable via -require-accounts. Accounts/ratings
+// are always available once the store is open, but anonymous play (a
+// room code shared with a friend, no /register or /login) stays the
+// default so requests #1-#5's flows keep working unauthenticated; set
+// this to force a bearer token on create/join/find_match.
+var requireAccounts = false
+
 // ═══════════════════════════════════════
 //  ROOM MANAGEMENT
 // ═══════════════════════════════════════
@@ -103,7 +148,7 @@ func createRoom() *Room {
 		}
 	}
 
-	room := &Room{Code: code}
+	room := &Room{Code: code, MaxPlayers: 2}
 	rooms[code] = room
 	log.Printf("Room created: %s", code)
 	return room
@@ -129,24 +174,44 @@ func handleWS(w http.ResponseWriter, r *http.Request) {
 	defer conn.Close()
 
 	player := &Player{
-		ID:   generatePlayerID(),
-		Conn: conn,
+		ID:         generatePlayerID(),
+		Conn:       conn,
+		LastActive: time.Now(),
 	}
 
 	var currentRoom *Room
 
 	log.Printf("Player connected: %s", player.ID)
 
+	conn.SetReadDeadline(time.Now().Add(idleTimeout))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		return nil
+	})
+
+	stopPinger := make(chan struct{})
+	defer close(stopPinger)
+	go runPinger(conn, stopPinger)
+
 	for {
 		_, msgBytes, err := conn.ReadMessage()
 		if err != nil {
 			log.Printf("Player %s disconnected: %v", player.ID, err)
+			if currentRoom == nil {
+				currentRoom = playerRoom(player)
+			}
 			if currentRoom != nil {
 				handleDisconnect(currentRoom, player)
+			} else {
+				dequeueMatch(player)
 			}
 			return
 		}
 
+		player.mu.Lock()
+		player.LastActive = time.Now()
+		player.mu.Unlock()
+
 		var msg Message
 		if err := json.Unmarshal(msgBytes, &msg); err != nil {
 			continue
@@ -154,19 +219,34 @@ func handleWS(w http.ResponseWriter, r *http.Request) {
 
 		switch msg.Type {
 		case "create":
+			if accountID, ok := authenticate(msg.Auth); ok {
+				player.ID = accountID
+			} else if requireAccounts {
+				sendJSON(conn, Message{Type: "error", Message: "Authentification requise"})
+				continue
+			}
 			room := createRoom()
 			room.mu.Lock()
 			room.Players = append(room.Players, player)
+			appendEvent(room, Event{Type: "joined", Player: player.ID})
 			room.mu.Unlock()
 			currentRoom = room
+			setPlayerRoom(player, room)
 
 			sendJSON(conn, Message{
 				Type:     "room_created",
 				Room:     room.Code,
 				PlayerID: player.ID,
+				Token:    newSessionToken(player.ID, room.Code),
 			})
 
 		case "join":
+			if accountID, ok := authenticate(msg.Auth); ok {
+				player.ID = accountID
+			} else if requireAccounts {
+				sendJSON(conn, Message{Type: "error", Message: "Authentification requise"})
+				continue
+			}
 			code := strings.ToUpper(strings.TrimSpace(msg.Room))
 			room := getOrCreateRoom(code)
 			if room == nil {
@@ -175,7 +255,7 @@ func handleWS(w http.ResponseWriter, r *http.Request) {
 			}
 
 			room.mu.Lock()
-			if len(room.Players) >= 2 {
+			if len(room.Players) >= room.MaxPlayers {
 				room.mu.Unlock()
 				sendJSON(conn, Message{Type: "error", Message: "Room pleine"})
 				continue
@@ -187,104 +267,203 @@ func handleWS(w http.ResponseWriter, r *http.Request) {
 			}
 
 			room.Players = append(room.Players, player)
+			appendEvent(room, Event{Type: "joined", Player: player.ID})
 			currentRoom = room
+			setPlayerRoom(player, room)
 
 			sendJSON(conn, Message{
 				Type:     "room_joined",
 				Room:     room.Code,
 				PlayerID: player.ID,
+				Token:    newSessionToken(player.ID, room.Code),
 			})
 
-			// If 2 players, start!
-			if len(room.Players) == 2 {
-				room.Started = true
+			// Once the room is full, start!
+			if len(room.Players) == room.MaxPlayers {
+				startRoomGame(room)
 				room.mu.Unlock()
 
 				for _, p := range room.Players {
-					sendJSON(p.Conn, Message{Type: "game_start", Room: room.Code})
+					grid := [4][4]int(p.Board)
+					sendJSON(p.Conn, Message{Type: "game_start", Room: room.Code, Grid: &grid})
 				}
 				log.Printf("Game started in room %s", room.Code)
 			} else {
 				room.mu.Unlock()
 			}
 
-		case "move":
-			if currentRoom == nil {
+		case "reconnect":
+			room, reconnected := handleReconnect(msg.Token, conn)
+			if reconnected == nil {
+				sendJSON(conn, Message{Type: "error", Message: "Session introuvable ou expirée"})
 				continue
 			}
-			// Broadcast player state to opponent
-			currentRoom.mu.Lock()
-			for _, p := range currentRoom.Players {
-				if p.ID != player.ID {
-					// The client handles the game logic locally,
-					// we just relay the move direction so the opponent's
-					// display stays in sync via state updates
-					sendJSON(p.Conn, Message{
-						Type:      "opponent_move",
-						Direction: msg.Direction,
-						PlayerID:  player.ID,
-					})
-				}
+			currentRoom = room
+			player = reconnected
+
+		case "find_match":
+			if accountID, ok := authenticate(msg.Auth); ok {
+				player.ID = accountID
+			} else if requireAccounts {
+				sendJSON(conn, Message{Type: "error", Message: "Authentification requise"})
+				continue
 			}
-			currentRoom.mu.Unlock()
+			enqueueMatch(player, msg.Rating)
+			sendJSON(conn, Message{Type: "searching"})
 
-		case "state_update":
-			// Player sends their current grid state
+		case "move":
+			if currentRoom == nil {
+				currentRoom = playerRoom(player)
+			}
 			if currentRoom == nil {
 				continue
 			}
+			dir, ok := game.ParseDirection(msg.Direction)
+			if !ok {
+				continue
+			}
+
 			currentRoom.mu.Lock()
-			for _, p := range currentRoom.Players {
-				if p.ID != player.ID {
-					sendJSON(p.Conn, Message{
-						Type:  "opponent_state",
-						Grid:  msg.Grid,
-						Score: msg.Score,
-					})
-				}
+			if !currentRoom.Started || currentRoom.Finished {
+				currentRoom.mu.Unlock()
+				continue
 			}
-			currentRoom.mu.Unlock()
 
-		case "game_won":
-			if currentRoom == nil {
+			player.mu.Lock()
+			if player.Lost || player.Won {
+				player.mu.Unlock()
+				currentRoom.mu.Unlock()
 				continue
 			}
-			currentRoom.mu.Lock()
+
+			moved, gained := player.Board.Move(dir)
+			appendEvent(currentRoom, Event{Type: "move", Player: player.ID, Direction: msg.Direction})
+			if moved {
+				if cell, value, spawned := player.Board.SpawnTile(currentRoom.rng); spawned {
+					appendEvent(currentRoom, Event{Type: "spawn", Player: player.ID, Cell: &cell, Value: value})
+				}
+				player.Score += gained
+				switch {
+				case player.Board.Has2048():
+					player.Won = true
+				case !player.Board.HasMoves():
+					player.Lost = true
+				}
+			}
+			grid := [4][4]int(player.Board)
+			score, lost, won := player.Score, player.Lost, player.Won
+			player.mu.Unlock()
+
 			for _, p := range currentRoom.Players {
-				sendJSON(p.Conn, Message{
-					Type:   "game_over",
-					Winner: player.ID,
-				})
+				if p.ID == player.ID {
+					sendJSON(p.Conn, Message{Type: "state_update", Grid: &grid, Score: score, Lost: lost, Won: won})
+				} else {
+					sendJSON(p.Conn, Message{Type: "opponent_state", PlayerID: player.ID, Grid: &grid, Score: score})
+				}
+			}
+
+			if won || lost {
+				currentRoom.Finished = true
+				winnerID := player.ID
+				for _, p := range currentRoom.Players {
+					p.mu.Lock()
+					if p.ID == player.ID {
+						p.Won, p.Lost = won, lost
+					} else {
+						p.Won, p.Lost = lost, won
+						if lost {
+							winnerID = p.ID
+						}
+					}
+					p.mu.Unlock()
+				}
+				appendEvent(currentRoom, Event{Type: "game_over", Winner: winnerID})
+				for _, p := range currentRoom.Players {
+					sendJSON(p.Conn, Message{Type: "game_over", Winner: winnerID})
+				}
+				settleMatch(currentRoom, winnerID)
+				log.Printf("Game over in room %s, winner: %s", currentRoom.Code, winnerID)
 			}
 			currentRoom.mu.Unlock()
-			log.Printf("Player %s won in room %s", player.ID, currentRoom.Code)
 		}
 	}
 }
 
+// handleDisconnect marks the player as disconnected instead of dropping them,
+// so a later "reconnect" with a valid session token can resume the match. If
+// the grace period elapses with no reconnect, finalizeDisconnect tears down
+// the player and forfeits the match to the opponent. A player the idle
+// monitor already kicked skips the grace period entirely; kickIdlePlayer
+// forfeits it directly.
 func handleDisconnect(room *Room, player *Player) {
-	room.mu.Lock()
-	defer room.mu.Unlock()
+	player.mu.Lock()
+	if player.Kicked {
+		player.mu.Unlock()
+		return
+	}
+	player.Disconnected = true
+	player.DisconnectedAt = time.Now()
+	player.reconnectTimer = time.AfterFunc(reconnectGracePeriod, func() {
+		finalizeDisconnect(room, player)
+	})
+	player.mu.Unlock()
 
-	// Notify other players
+	room.mu.Lock()
 	for _, p := range room.Players {
 		if p.ID != player.ID {
 			sendJSON(p.Conn, Message{
-				Type:    "error",
-				Message: "L'adversaire s'est déconnecté",
+				Type:    "opponent_disconnected",
+				Message: "L'adversaire s'est déconnecté, en attente de reconnexion",
 			})
 		}
 	}
+	room.mu.Unlock()
+}
+
+// finalizeDisconnect removes a player whose reconnect grace period expired
+// and forfeits the match to the remaining opponent, if any.
+func finalizeDisconnect(room *Room, player *Player) {
+	player.mu.Lock()
+	stillGone := player.Disconnected
+	player.mu.Unlock()
+	if !stillGone {
+		// They reconnected in the meantime; nothing to do.
+		return
+	}
 
-	// Clean up
-	remaining := make([]*Player, 0)
+	room.mu.Lock()
+	forfeit(room, player, "L'adversaire a abandonné (déconnexion)")
+	room.mu.Unlock()
+}
+
+// forfeit removes player from the room and declares every remaining
+// player the winner. It's idempotent: a no-op if player is already gone,
+// so a racing kick and disconnect can't double-forfeit the same match.
+// Callers must hold room.mu.
+func forfeit(room *Room, player *Player, message string) {
+	found := false
+	remaining := make([]*Player, 0, len(room.Players))
 	for _, p := range room.Players {
-		if p.ID != player.ID {
-			remaining = append(remaining, p)
+		if p.ID == player.ID {
+			found = true
+			continue
 		}
+		remaining = append(remaining, p)
+	}
+	if !found {
+		return
+	}
+	if len(remaining) == 1 && !room.Finished {
+		room.Finished = true
+		settleMatch(room, remaining[0].ID)
 	}
 	room.Players = remaining
 
+	for _, p := range remaining {
+		appendEvent(room, Event{Type: "game_over", Winner: p.ID})
+		sendJSON(p.Conn, Message{Type: "game_over", Winner: p.ID, Message: message})
+	}
+
 	if len(room.Players) == 0 {
 		go removeRoom(room.Code)
 	}
@@ -303,8 +482,20 @@ func sendJSON(conn *websocket.Conn, msg Message) {
 // ═══════════════════════════════════════
 
 func main() {
+	flag.DurationVar(&pingInterval, "ping-interval", pingInterval, "interval between server-side WebSocket pings")
+	flag.DurationVar(&idleTimeout, "idle-timeout", idleTimeout, "how long a started game tolerates a player with no inbound messages before kicking them")
+	dbPath := flag.String("db-path", "2048-royale.db", "path to the SQLite database backing accounts, ratings and match history")
+	flag.BoolVar(&requireAccounts, "require-accounts", requireAccounts, "refuse create/join/find_match without a bearer token from /login (anonymous play is allowed by default)")
+	flag.Parse()
+
 	rand.Seed(time.Now().UnixNano())
 
+	db, err := storage.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("failed to open database: %v", err)
+	}
+	store = db
+
 	// Serve static files
 	fs := http.FileServer(http.Dir("../"))
 	http.Handle("/", fs)
@@ -312,6 +503,19 @@ func main() {
 	// WebSocket endpoint
 	http.HandleFunc("/ws", handleWS)
 
+	// Replay & spectating
+	http.HandleFunc("/replay/", handleReplay)
+	http.HandleFunc("/spectate/", handleSpectate)
+
+	// Matchmaking control plane
+	http.HandleFunc("/games", handleGames)
+	go runMatchmaker()
+
+	// Accounts & ratings
+	http.HandleFunc("/register", handleRegister)
+	http.HandleFunc("/login", handleLogin)
+	http.HandleFunc("/players/", handlePlayerProfile)
+
 	port := ":8080"
 	fmt.Println("╔═══════════════════════════════════════╗")
 	fmt.Println("║       2048 Royale — Server            ║")
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ═══════════════════════════════════════
+//  PLAYER ↔ ROOM ASSIGNMENT
+// ═══════════════════════════════════════
+
+// setPlayerRoom and playerRoom let the matchmaker assign a room to a
+// player from outside that player's own handleWS goroutine.
+func setPlayerRoom(player *Player, room *Room) {
+	player.mu.Lock()
+	player.Room = room
+	player.mu.Unlock()
+}
+
+func playerRoom(player *Player) *Room {
+	player.mu.Lock()
+	defer player.mu.Unlock()
+	return player.Room
+}
+
+// startRoomGame seeds the room's shared RNG, deals each player their
+// opening tiles, and logs both as replay events. Callers must already
+// hold room.mu and have room.Players filled to room.MaxPlayers.
+func startRoomGame(room *Room) {
+	room.Started = true
+	room.StartedAt = time.Now()
+	room.Seed = time.Now().UnixNano()
+	room.rng = rand.New(rand.NewSource(room.Seed))
+	appendEvent(room, Event{Type: "game_start", Seed: room.Seed})
+
+	for _, p := range room.Players {
+		for i := 0; i < 2; i++ {
+			if cell, value, spawned := p.Board.SpawnTile(room.rng); spawned {
+				appendEvent(room, Event{Type: "spawn", Player: p.ID, Cell: &cell, Value: value})
+			}
+		}
+		p.mu.Lock()
+		p.LastActive = time.Now()
+		p.mu.Unlock()
+	}
+
+	go monitorIdlePlayers(room)
+}
+
+// ═══════════════════════════════════════
+//  MATCHMAKING
+// ═══════════════════════════════════════
+
+// ratingBand is how far apart two players' ratings may be and still be
+// considered a fair pairing. Unrated players (Rating == 0 on both sides)
+// always match.
+const ratingBand = 100
+
+type queuedPlayer struct {
+	Player   *Player
+	Rating   int
+	QueuedAt time.Time
+}
+
+var (
+	matchQueue   []*queuedPlayer
+	matchQueueMu sync.Mutex
+)
+
+// enqueueMatch adds a player to the FIFO matchmaking queue. The next
+// matchmaker tick will pair them with a compatible waiting player, if any.
+func enqueueMatch(player *Player, rating int) {
+	matchQueueMu.Lock()
+	defer matchQueueMu.Unlock()
+	matchQueue = append(matchQueue, &queuedPlayer{Player: player, Rating: rating, QueuedAt: time.Now()})
+}
+
+// dequeueMatch removes a player from the matchmaking queue, e.g. because
+// they disconnected while still waiting. No-op if they aren't queued.
+func dequeueMatch(player *Player) {
+	matchQueueMu.Lock()
+	defer matchQueueMu.Unlock()
+	remaining := matchQueue[:0]
+	for _, q := range matchQueue {
+		if q.Player.ID != player.ID {
+			remaining = append(remaining, q)
+		}
+	}
+	matchQueue = remaining
+}
+
+// runMatchmaker pairs waiting players into fresh public rooms. It runs for
+// the lifetime of the process.
+func runMatchmaker() {
+	ticker := time.NewTicker(300 * time.Millisecond)
+	defer ticker.Stop()
+	for range ticker.C {
+		pairQueuedPlayers()
+	}
+}
+
+func ratingCompatible(a, b *queuedPlayer) bool {
+	if a.Rating == 0 && b.Rating == 0 {
+		return true
+	}
+	diff := a.Rating - b.Rating
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= ratingBand
+}
+
+func pairQueuedPlayers() {
+	matchQueueMu.Lock()
+	matched := make(map[int]bool)
+	var pairs [][2]*queuedPlayer
+	for i := 0; i < len(matchQueue); i++ {
+		if matched[i] {
+			continue
+		}
+		for j := i + 1; j < len(matchQueue); j++ {
+			if matched[j] {
+				continue
+			}
+			if ratingCompatible(matchQueue[i], matchQueue[j]) {
+				matched[i], matched[j] = true, true
+				pairs = append(pairs, [2]*queuedPlayer{matchQueue[i], matchQueue[j]})
+				break
+			}
+		}
+	}
+	if len(matched) > 0 {
+		remaining := matchQueue[:0]
+		for i, q := range matchQueue {
+			if !matched[i] {
+				remaining = append(remaining, q)
+			}
+		}
+		matchQueue = remaining
+	}
+	matchQueueMu.Unlock()
+
+	for _, pair := range pairs {
+		startMatch(pair[0].Player, pair[1].Player)
+	}
+}
+
+// startMatch pairs two queued players into a freshly created public room
+// and kicks off the game, mirroring the two-player "join" flow.
+func startMatch(a, b *Player) {
+	room := createRoom()
+	room.mu.Lock()
+	room.Public = true
+	room.Players = append(room.Players, a, b)
+	appendEvent(room, Event{Type: "joined", Player: a.ID})
+	appendEvent(room, Event{Type: "joined", Player: b.ID})
+	startRoomGame(room)
+	for _, p := range room.Players {
+		setPlayerRoom(p, room)
+	}
+	room.mu.Unlock()
+
+	for _, p := range room.Players {
+		grid := [4][4]int(p.Board)
+		sendJSON(p.Conn, Message{
+			Type:  "game_start",
+			Room:  room.Code,
+			Grid:  &grid,
+			Token: newSessionToken(p.ID, room.Code),
+		})
+	}
+	log.Printf("Matchmade game started in room %s", room.Code)
+}
+
+// ═══════════════════════════════════════
+//  GAME LISTING
+// ═══════════════════════════════════════
+
+type gameListing struct {
+	Code       string `json:"code"`
+	Players    int    `json:"players"`
+	MaxPlayers int    `json:"max_players"`
+	Started    bool   `json:"started"`
+	Spectators int    `json:"spectators"`
+}
+
+// handleGames serves GET /games (public room listing) and POST /games
+// (create a new public room), the control-plane split a web lobby UI
+// needs alongside the /ws game socket.
+func handleGames(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		listGames(w, r)
+	case http.MethodPost:
+		startGame(w, r)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func listGames(w http.ResponseWriter, r *http.Request) {
+	roomsMu.RLock()
+	listings := make([]gameListing, 0, len(rooms))
+	for _, room := range rooms {
+		room.mu.Lock()
+		if !room.Public {
+			room.mu.Unlock()
+			continue
+		}
+		room.specMu.Lock()
+		spectators := len(room.Spectators)
+		room.specMu.Unlock()
+		listings = append(listings, gameListing{
+			Code:       room.Code,
+			Players:    len(room.Players),
+			MaxPlayers: room.MaxPlayers,
+			Started:    room.Started,
+			Spectators: spectators,
+		})
+		room.mu.Unlock()
+	}
+	roomsMu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listings)
+}
+
+// startGame creates a new public room for a web lobby to advertise and
+// returns its code; players still join over /ws with a "join" message.
+func startGame(w http.ResponseWriter, r *http.Request) {
+	room := createRoom()
+	room.mu.Lock()
+	room.Public = true
+	room.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Code string `json:"code"`
+	}{Code: room.Code})
+}
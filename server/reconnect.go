@@ -0,0 +1,170 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ═══════════════════════════════════════
+//  RECONNECTION
+// ═══════════════════════════════════════
+
+// reconnectGracePeriod is how long a disconnected player's seat is held
+// before the player is removed from the room and the match is forfeited
+// to the opponent. The room itself is removed only once no players remain.
+const reconnectGracePeriod = 60 * time.Second
+
+// sessionSecret HMAC-signs session tokens so a client can't forge a
+// reconnect for a player it doesn't own. Generated fresh per process start;
+// restarting the server invalidates any outstanding tokens.
+var sessionSecret = func() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("failed to generate session secret: %v", err))
+	}
+	return secret
+}()
+
+// newSessionToken issues a signed token binding a player to a room so they
+// can rejoin after a dropped connection. Format is "payload.signature",
+// where payload is base64(playerID|roomCode|nonce).
+func newSessionToken(playerID, roomCode string) string {
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+
+	payload := strings.Join([]string{playerID, roomCode, base64.RawURLEncoding.EncodeToString(nonce)}, "|")
+	encodedPayload := base64.RawURLEncoding.EncodeToString([]byte(payload))
+
+	mac := hmac.New(sha256.New, sessionSecret)
+	mac.Write([]byte(encodedPayload))
+	sig := hex.EncodeToString(mac.Sum(nil))
+
+	return encodedPayload + "." + sig
+}
+
+// verifySessionToken checks the token's signature and returns the bound
+// player ID and room code.
+func verifySessionToken(token string) (playerID, roomCode string, ok bool) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	encodedPayload, sig := parts[0], parts[1]
+
+	mac := hmac.New(sha256.New, sessionSecret)
+	mac.Write([]byte(encodedPayload))
+	expectedSig := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(sig), []byte(expectedSig)) {
+		return "", "", false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", "", false
+	}
+	fields := strings.Split(string(payload), "|")
+	if len(fields) != 3 {
+		return "", "", false
+	}
+	return fields[0], fields[1], true
+}
+
+// handleReconnect validates a session token, swaps the new connection into
+// the existing Player, cancels its grace-period timer, and resends enough
+// state for the client to rehydrate the board. Returns (nil, nil) if the
+// token is invalid or the room/player no longer exists.
+func handleReconnect(token string, conn *websocket.Conn) (*Room, *Player) {
+	playerID, roomCode, ok := verifySessionToken(token)
+	if !ok {
+		return nil, nil
+	}
+
+	room := getOrCreateRoom(roomCode)
+	if room == nil {
+		return nil, nil
+	}
+
+	room.mu.Lock()
+	var player *Player
+	for _, p := range room.Players {
+		if p.ID == playerID {
+			player = p
+			break
+		}
+	}
+	if player == nil {
+		room.mu.Unlock()
+		return nil, nil
+	}
+
+	player.mu.Lock()
+	if !player.Disconnected {
+		// Session is still live elsewhere (duplicate tab, resent/leaked
+		// token) — refuse rather than hijack the live connection and
+		// strand its handleWS goroutine on a Conn nobody writes to again.
+		player.mu.Unlock()
+		room.mu.Unlock()
+		return nil, nil
+	}
+	if player.reconnectTimer != nil {
+		player.reconnectTimer.Stop()
+		player.reconnectTimer = nil
+	}
+	staleConn := player.Conn
+	player.Conn = conn
+	player.Room = room
+	player.Disconnected = false
+	player.DisconnectedAt = time.Time{}
+	player.LastActive = time.Now()
+
+	var opponent *Player
+	for _, p := range room.Players {
+		if p.ID != playerID {
+			opponent = p
+			break
+		}
+	}
+
+	grid := [4][4]int(player.Board)
+	score := player.Score
+	lost := player.Lost
+	won := player.Won
+	player.mu.Unlock()
+	room.mu.Unlock()
+
+	if staleConn != nil && staleConn != conn {
+		staleConn.Close()
+	}
+
+	resend := Message{
+		Type:  "reconnected",
+		Room:  room.Code,
+		Grid:  &grid,
+		Score: score,
+		Lost:  lost,
+		Won:   won,
+	}
+	if opponent != nil {
+		opponent.mu.Lock()
+		opponentGrid := [4][4]int(opponent.Board)
+		opponentScore := opponent.Score
+		opponent.mu.Unlock()
+		resend.OpponentGrid = &opponentGrid
+		resend.OpponentScore = opponentScore
+
+		sendJSON(opponent.Conn, Message{Type: "opponent_reconnected"})
+	}
+	sendJSON(conn, resend)
+
+	log.Printf("Player %s reconnected to room %s", player.ID, room.Code)
+	return room, player
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ═══════════════════════════════════════
+//  REPLAY & SPECTATING
+// ═══════════════════════════════════════
+
+// Event is one entry in a Room's append-only log. Combined with the
+// room's Seed, replaying every move/spawn in order reconstructs the
+// match tile-by-tile.
+type Event struct {
+	Seq       int       `json:"seq"`
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	Player    string    `json:"player,omitempty"`
+	Direction string    `json:"direction,omitempty"`
+	Cell      *[2]int   `json:"cell,omitempty"`
+	Value     int       `json:"value,omitempty"`
+	Winner    string    `json:"winner,omitempty"`
+	Seed      int64     `json:"seed,omitempty"`
+}
+
+// appendEvent records ev in the room's log with the next sequence number
+// and fans it out to any connected spectators. Callers must already hold
+// room.mu.
+func appendEvent(room *Room, ev Event) Event {
+	ev.Seq = room.nextSeq
+	room.nextSeq++
+	ev.Time = time.Now()
+	room.Events = append(room.Events, ev)
+
+	broadcastEvent(room, ev)
+	return ev
+}
+
+// broadcastEvent fans ev out to every connected spectator, dropping (and
+// pruning) any socket that errors on write.
+func broadcastEvent(room *Room, ev Event) {
+	room.specMu.Lock()
+	defer room.specMu.Unlock()
+	if len(room.Spectators) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+
+	live := room.Spectators[:0]
+	for _, conn := range room.Spectators {
+		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			conn.Close()
+			continue
+		}
+		live = append(live, conn)
+	}
+	room.Spectators = live
+}
+
+// spectatorSnapshot is sent once, right after a spectator connects, so
+// their client can render the match so far before following live events.
+type spectatorSnapshot struct {
+	Type   string       `json:"type"`
+	Room   string       `json:"room"`
+	Seed   int64        `json:"seed"`
+	Events []Event      `json:"events"`
+	Boards []boardState `json:"boards"`
+}
+
+type boardState struct {
+	PlayerID string    `json:"player_id"`
+	Grid     [4][4]int `json:"grid"`
+	Score    int       `json:"score"`
+	Lost     bool      `json:"lost"`
+	Won      bool      `json:"won"`
+}
+
+// handleReplay serves the finished event log for a room as JSON, so a
+// client can reconstruct the match tile-by-tile from Seed + Events.
+func handleReplay(w http.ResponseWriter, r *http.Request) {
+	code := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/replay/"), ".json")
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	room := getOrCreateRoom(code)
+	if room == nil {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+
+	room.mu.Lock()
+	snapshot := struct {
+		Room   string  `json:"room"`
+		Seed   int64   `json:"seed"`
+		Events []Event `json:"events"`
+	}{Room: room.Code, Seed: room.Seed, Events: room.Events}
+	room.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		log.Printf("replay encode error for room %s: %v", code, err)
+	}
+}
+
+// handleSpectate upgrades a read-only connection, sends it a snapshot of
+// the match so far, then streams every new event as it happens. Spectator
+// sockets never get to write a move; they're tracked separately from
+// Room.Players.
+func handleSpectate(w http.ResponseWriter, r *http.Request) {
+	code := strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(r.URL.Path, "/spectate/")))
+
+	room := getOrCreateRoom(code)
+	if room == nil {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Spectate upgrade error: %v", err)
+		return
+	}
+
+	room.mu.Lock()
+	snapshot := spectatorSnapshot{
+		Type:   "spectate_snapshot",
+		Room:   room.Code,
+		Seed:   room.Seed,
+		Events: append([]Event(nil), room.Events...),
+	}
+	for _, p := range room.Players {
+		p.mu.Lock()
+		snapshot.Boards = append(snapshot.Boards, boardState{
+			PlayerID: p.ID,
+			Grid:     [4][4]int(p.Board),
+			Score:    p.Score,
+			Lost:     p.Lost,
+			Won:      p.Won,
+		})
+		p.mu.Unlock()
+	}
+
+	// Register before releasing room.mu, in the same critical section as
+	// the snapshot read, so no event appended in between is missed: it
+	// either lands in Events (captured above) or gets broadcast to this
+	// spectator (registered below), never neither.
+	room.specMu.Lock()
+	room.Spectators = append(room.Spectators, conn)
+	room.specMu.Unlock()
+	room.mu.Unlock()
+
+	data, err := json.Marshal(snapshot)
+	if err == nil {
+		conn.WriteMessage(websocket.TextMessage, data)
+	}
+
+	log.Printf("Spectator joined room %s", room.Code)
+
+	// Spectators don't send anything meaningful; just drain reads so
+	// we notice the socket closing and can prune it.
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			room.specMu.Lock()
+			live := room.Spectators[:0]
+			for _, c := range room.Spectators {
+				if c != conn {
+					live = append(live, c)
+				}
+			}
+			room.Spectators = live
+			room.specMu.Unlock()
+			conn.Close()
+			return
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Package storage persists accounts and match history to SQLite so
+// ratings and history survive a server restart. It uses modernc.org/sqlite,
+// a pure-Go driver, so the binary stays cgo-free.
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// ErrNotFound is returned by lookups that find no matching row.
+var ErrNotFound = errors.New("storage: not found")
+
+// Store wraps the SQLite connection used for accounts and match history.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if necessary) the SQLite database at path and runs
+// migrations.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("ping sqlite: %w", err)
+	}
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) migrate() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS players (
+			id            TEXT PRIMARY KEY,
+			name          TEXT NOT NULL UNIQUE,
+			password_hash TEXT NOT NULL,
+			rating        INTEGER NOT NULL DEFAULT 1000,
+			created_at    DATETIME NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS matches (
+			id          TEXT PRIMARY KEY,
+			room        TEXT NOT NULL,
+			player_a    TEXT NOT NULL,
+			player_b    TEXT NOT NULL,
+			winner      TEXT NOT NULL,
+			score_a     INTEGER NOT NULL,
+			score_b     INTEGER NOT NULL,
+			moves       INTEGER NOT NULL,
+			duration_ms INTEGER NOT NULL,
+			ended_at    DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_matches_player_a ON matches(player_a);
+		CREATE INDEX IF NOT EXISTS idx_matches_player_b ON matches(player_b);
+	`)
+	return err
+}
+
+// Player is an account row.
+type Player struct {
+	ID           string
+	Name         string
+	PasswordHash string
+	Rating       int
+	CreatedAt    time.Time
+}
+
+// Match is one finished game's record.
+type Match struct {
+	ID       string
+	Room     string
+	PlayerA  string
+	PlayerB  string
+	Winner   string
+	ScoreA   int
+	ScoreB   int
+	Moves    int
+	Duration time.Duration
+	EndedAt  time.Time
+}
+
+// CreatePlayer inserts a new account. It fails if the name is already taken.
+func (s *Store) CreatePlayer(p Player) error {
+	_, err := s.db.Exec(
+		`INSERT INTO players (id, name, password_hash, rating, created_at) VALUES (?, ?, ?, ?, ?)`,
+		p.ID, p.Name, p.PasswordHash, p.Rating, p.CreatedAt,
+	)
+	return err
+}
+
+// PlayerByName looks up an account by its unique display name.
+func (s *Store) PlayerByName(name string) (Player, error) {
+	return s.scanPlayer(s.db.QueryRow(
+		`SELECT id, name, password_hash, rating, created_at FROM players WHERE name = ?`, name,
+	))
+}
+
+// PlayerByID looks up an account by its stable ID.
+func (s *Store) PlayerByID(id string) (Player, error) {
+	return s.scanPlayer(s.db.QueryRow(
+		`SELECT id, name, password_hash, rating, created_at FROM players WHERE id = ?`, id,
+	))
+}
+
+func (s *Store) scanPlayer(row *sql.Row) (Player, error) {
+	var p Player
+	if err := row.Scan(&p.ID, &p.Name, &p.PasswordHash, &p.Rating, &p.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Player{}, ErrNotFound
+		}
+		return Player{}, err
+	}
+	return p, nil
+}
+
+// UpdateRating persists a player's new rating after a match.
+func (s *Store) UpdateRating(id string, rating int) error {
+	_, err := s.db.Exec(`UPDATE players SET rating = ? WHERE id = ?`, rating, id)
+	return err
+}
+
+// RecordMatch inserts a finished match's result.
+func (s *Store) RecordMatch(m Match) error {
+	_, err := s.db.Exec(
+		`INSERT INTO matches (id, room, player_a, player_b, winner, score_a, score_b, moves, duration_ms, ended_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		m.ID, m.Room, m.PlayerA, m.PlayerB, m.Winner, m.ScoreA, m.ScoreB, m.Moves, m.Duration.Milliseconds(), m.EndedAt,
+	)
+	return err
+}
+
+// MatchesForPlayer returns every match a player took part in, most recent first.
+func (s *Store) MatchesForPlayer(id string) ([]Match, error) {
+	rows, err := s.db.Query(
+		`SELECT id, room, player_a, player_b, winner, score_a, score_b, moves, duration_ms, ended_at
+		 FROM matches WHERE player_a = ? OR player_b = ? ORDER BY ended_at DESC`,
+		id, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []Match
+	for rows.Next() {
+		var m Match
+		var durationMS int64
+		if err := rows.Scan(&m.ID, &m.Room, &m.PlayerA, &m.PlayerB, &m.Winner, &m.ScoreA, &m.ScoreB, &m.Moves, &durationMS, &m.EndedAt); err != nil {
+			return nil, err
+		}
+		m.Duration = time.Duration(durationMS) * time.Millisecond
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}